@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MultiClusterServiceSpec defines a Service that a tenant exports to a set of
+// peer virtual clusters sharing the same super master, modeled after the
+// ServiceExport/ServiceImport semantics of KEP-1645 multi-cluster services.
+type MultiClusterServiceSpec struct {
+	// ServiceName is the name of the Service, in the same namespace as this
+	// MultiClusterService, that is being exported.
+	ServiceName string `json:"serviceName"`
+
+	// ExportClusters lists the virtual cluster names allowed to import this
+	// Service. An empty list exports to no one; there is no "export to all"
+	// wildcard so that cross-tenant visibility is always explicit.
+	ExportClusters []string `json:"exportClusters,omitempty"`
+}
+
+// MultiClusterServiceStatus reflects the last observed state of the export.
+type MultiClusterServiceStatus struct {
+	// ImportedClusters lists the virtual clusters that currently have a
+	// projected headless Service + EndpointSlice for this export.
+	ImportedClusters []string `json:"importedClusters,omitempty"`
+
+	// Conditions represent the latest available observations of the export's
+	// current state.
+	Conditions []MultiClusterServiceCondition `json:"conditions,omitempty"`
+}
+
+// MultiClusterServiceConditionType is the type of a MultiClusterService condition.
+type MultiClusterServiceConditionType string
+
+const (
+	// MultiClusterServiceExported indicates whether the source Service has
+	// been successfully projected into all of ExportClusters.
+	MultiClusterServiceExported MultiClusterServiceConditionType = "Exported"
+)
+
+// MultiClusterServiceCondition describes the state of a MultiClusterService at a point in time.
+type MultiClusterServiceCondition struct {
+	Type               MultiClusterServiceConditionType `json:"type"`
+	Status             metav1.ConditionStatus           `json:"status"`
+	LastTransitionTime metav1.Time                      `json:"lastTransitionTime,omitempty"`
+	Reason             string                           `json:"reason,omitempty"`
+	Message            string                           `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// MultiClusterService lets a tenant export a Service in its virtual cluster
+// to a set of peer virtual clusters on the same super master, so it can be
+// consumed there under a `<service>.<namespace>.svc.clusterset.local`-style
+// name without granting the importing tenants cross-tenant RBAC.
+type MultiClusterService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MultiClusterServiceSpec   `json:"spec,omitempty"`
+	Status MultiClusterServiceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MultiClusterServiceList contains a list of MultiClusterService.
+type MultiClusterServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MultiClusterService `json:"items"`
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package constants holds shared defaults and well-known label/annotation
+// keys used across the syncer resource controllers.
+package constants
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultControllerWorkers is the default number of worker goroutines a
+	// resource controller starts for each of its work queues.
+	DefaultControllerWorkers = 3
+
+	// LabelCluster is the pService annotation key recording the virtual
+	// cluster name that owns it.
+	LabelCluster = "tenancy.x-k8s.io/cluster"
+	// LabelNamespace is the pService annotation key recording the owning
+	// object's namespace in the virtual cluster.
+	LabelNamespace = "tenancy.x-k8s.io/namespace"
+	// LabelUID is the pService annotation key recording the owning object's
+	// UID in the virtual cluster, used to detect tenant object recreation.
+	LabelUID = "tenancy.x-k8s.io/uid"
+)
+
+// DefaultDeletionPolicy is the propagation policy used when a resource
+// controller deletes a pObject on behalf of a tenant.
+var DefaultDeletionPolicy = metav1.DeletePropagationBackground
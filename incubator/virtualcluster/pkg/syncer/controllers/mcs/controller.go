@@ -0,0 +1,324 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mcs watches the MultiClusterService CRD across tenant clusters and
+// projects each export's Service into the super master namespaces of the
+// peer virtual clusters that import it, so tenants can consume a Service
+// owned by another virtual cluster on the same super master without
+// cross-tenant RBAC.
+package mcs
+
+import (
+	"fmt"
+
+	discovery "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	discoveryv1beta1 "k8s.io/client-go/kubernetes/typed/discovery/v1beta1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/klog"
+
+	tenancyv1alpha1 "github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/cluster"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/constants"
+	sc "github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/controller"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/conversion"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/manager"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/reconciler"
+)
+
+// importedServiceLabel marks a projected headless Service/EndpointSlice with
+// the MultiClusterService that owns it, so pruneImports can find and clean
+// up everything it created when an export shrinks or is deleted.
+const importedServiceLabel = "tenancy.x-k8s.io/imported-from"
+
+// peerClusterAnnotation records which virtual cluster a projected Service was
+// imported into, so pruneImports never has to reverse-parse it back out of
+// the super master namespace name.
+const peerClusterAnnotation = "tenancy.x-k8s.io/peer-cluster"
+
+type controller struct {
+	serviceClient       v1core.ServicesGetter
+	endpointSliceClient discoveryv1beta1.EndpointSlicesGetter
+
+	multiClusterMCSController *sc.MultiClusterController
+
+	serviceLister   listersv1.ServiceLister
+	endpointsLister listersv1.EndpointsLister
+}
+
+// Register wires the mcs controller into controllerManager, following the
+// same Register/AddCluster pattern as the sibling service controller: the
+// MultiClusterService CRD is watched per tenant cluster via
+// sc.MultiClusterController, and the projection is written into the super
+// master with the super-master-scoped serviceClient/endpointSliceClient -
+// tenants never need direct access to the super master.
+func Register(
+	serviceClient v1core.ServicesGetter,
+	endpointSliceClient discoveryv1beta1.EndpointSlicesGetter,
+	serviceLister listersv1.ServiceLister,
+	endpointsLister listersv1.EndpointsLister,
+	controllerManager *manager.ControllerManager,
+) {
+	c := &controller{
+		serviceClient:       serviceClient,
+		endpointSliceClient: endpointSliceClient,
+		serviceLister:       serviceLister,
+		endpointsLister:     endpointsLister,
+	}
+
+	options := sc.Options{Reconciler: c}
+	multiClusterMCSController, err := sc.NewController("tenant-masters-mcs-controller", &tenancyv1alpha1.MultiClusterService{}, options)
+	if err != nil {
+		klog.Errorf("failed to create multi cluster mcs controller %v", err)
+		return
+	}
+	c.multiClusterMCSController = multiClusterMCSController
+
+	controllerManager.AddController(c)
+}
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	return c.multiClusterMCSController.Start(stopCh)
+}
+
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.Infof("reconcile mcs %s/%s %s event for cluster %s", request.Namespace, request.Name, request.Event, request.Cluster.Name)
+
+	mcs := request.Obj.(*tenancyv1alpha1.MultiClusterService)
+	switch request.Event {
+	case reconciler.AddEvent, reconciler.UpdateEvent:
+		if err := c.reconcileMCS(request.Cluster.Name, request.Namespace, mcs); err != nil {
+			klog.Errorf("failed reconcile mcs %s/%s of cluster %s: %v", request.Namespace, request.Name, request.Cluster.Name, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	case reconciler.DeleteEvent:
+		if err := c.pruneImports(request.Name, nil); err != nil {
+			klog.Errorf("failed reconcile mcs %s/%s DELETE of cluster %s: %v", request.Namespace, request.Name, request.Cluster.Name, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	}
+	return reconciler.Result{}, nil
+}
+
+// reconcileMCS projects mcs's source Service into every cluster named in
+// ExportClusters, then prunes any previously projected import that is no
+// longer in that list.
+func (c *controller) reconcileMCS(cluster, namespace string, mcs *tenancyv1alpha1.MultiClusterService) error {
+	sourceNamespace := conversion.ToSuperMasterNamespace(cluster, namespace)
+
+	sourceService, err := c.serviceLister.Services(sourceNamespace).Get(mcs.Spec.ServiceName)
+	if errors.IsNotFound(err) {
+		klog.Warningf("mcs %s/%s exports nonexistent service %s, nothing to project", namespace, mcs.Name, mcs.Spec.ServiceName)
+		return c.pruneImports(mcs.Name, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	sourceEndpoints, err := c.endpointsLister.Endpoints(sourceNamespace).Get(mcs.Spec.ServiceName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	imported := make([]string, 0, len(mcs.Spec.ExportClusters))
+	for _, peerCluster := range mcs.Spec.ExportClusters {
+		if peerCluster == cluster {
+			klog.Warningf("mcs %s/%s of cluster %s lists its own cluster in exportClusters, skipping self-import", namespace, mcs.Name, cluster)
+			continue
+		}
+		if err := c.projectToCluster(mcs.Name, namespace, sourceService, sourceEndpoints, peerCluster); err != nil {
+			return err
+		}
+		imported = append(imported, peerCluster)
+	}
+
+	return c.pruneImports(mcs.Name, imported)
+}
+
+// projectToCluster creates or updates the headless Service and EndpointSlice
+// for mcsName in peerCluster's super master namespace, pointed at the
+// source Service's backend pod IPs harvested from the endpoints syncer.
+func (c *controller) projectToCluster(mcsName, namespace string, source *v1.Service, endpoints *v1.Endpoints, peerCluster string) error {
+	targetNamespace := conversion.ToSuperMasterNamespace(peerCluster, namespace)
+	importedName := source.Name
+
+	headless := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      importedName,
+			Namespace: targetNamespace,
+			Labels:    map[string]string{importedServiceLabel: mcsName},
+			Annotations: map[string]string{
+				peerClusterAnnotation: peerCluster,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Ports:     source.Spec.Ports,
+		},
+	}
+	if err := c.createOrUpdateService(headless, mcsName, peerCluster); err != nil {
+		return err
+	}
+
+	slice := buildEndpointSlice(mcsName, peerCluster, importedName, targetNamespace, source, endpoints)
+	return c.createOrUpdateEndpointSlice(slice, mcsName, peerCluster)
+}
+
+// buildEndpointSlice harvests backend addresses from the source Service's
+// Endpoints (populated by the existing endpoints syncer) so the imported
+// Service routes directly to pod IPs rather than to a ClusterIP the peer
+// tenant's super master namespace cannot route to.
+func buildEndpointSlice(mcsName, peerCluster, name, namespace string, source *v1.Service, endpoints *v1.Endpoints) *discovery.EndpointSlice {
+	var addresses []string
+	if endpoints != nil {
+		for _, subset := range endpoints.Subsets {
+			for _, addr := range subset.Addresses {
+				addresses = append(addresses, addr.IP)
+			}
+		}
+	}
+
+	ports := make([]discovery.EndpointPort, 0, len(source.Spec.Ports))
+	for i := range source.Spec.Ports {
+		p := source.Spec.Ports[i]
+		ports = append(ports, discovery.EndpointPort{
+			Name:     &p.Name,
+			Port:     &p.Port,
+			Protocol: &p.Protocol,
+		})
+	}
+
+	return &discovery.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				importedServiceLabel:       mcsName,
+				discovery.LabelServiceName: name,
+			},
+			Annotations: map[string]string{
+				peerClusterAnnotation: peerCluster,
+			},
+		},
+		AddressType: discovery.AddressTypeIPv4,
+		Endpoints: []discovery.Endpoint{
+			{Addresses: addresses},
+		},
+		Ports: ports,
+	}
+}
+
+// createOrUpdateService creates svc if its namespace/name is free, and
+// otherwise only updates it if the existing object is itself a projection of
+// this same mcsName/peerCluster import - never an unrelated Service that
+// merely happens to collide on name, which could be a tenant's own Service
+// in its super master namespace, or (if a tenant lists its own cluster in
+// ExportClusters) the very source Service this export is reading from.
+func (c *controller) createOrUpdateService(svc *v1.Service, mcsName, peerCluster string) error {
+	existing, err := c.serviceLister.Services(svc.Namespace).Get(svc.Name)
+	if errors.IsNotFound(err) {
+		_, err = c.serviceClient.Services(svc.Namespace).Create(svc)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Labels[importedServiceLabel] != mcsName || existing.Annotations[peerClusterAnnotation] != peerCluster {
+		return fmt.Errorf("refusing to import mcs %s into cluster %s: service %s/%s already exists and is not one of our imports", mcsName, peerCluster, svc.Namespace, svc.Name)
+	}
+
+	svc.ResourceVersion = existing.ResourceVersion
+	_, err = c.serviceClient.Services(svc.Namespace).Update(svc)
+	return err
+}
+
+// createOrUpdateEndpointSlice mirrors createOrUpdateService's ownership
+// check: an EndpointSlice that already exists under this name is only
+// updated if it is itself one of our own imports.
+func (c *controller) createOrUpdateEndpointSlice(slice *discovery.EndpointSlice, mcsName, peerCluster string) error {
+	_, err := c.endpointSliceClient.EndpointSlices(slice.Namespace).Create(slice)
+	if errors.IsAlreadyExists(err) {
+		existing, getErr := c.endpointSliceClient.EndpointSlices(slice.Namespace).Get(slice.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		if existing.Labels[importedServiceLabel] != mcsName || existing.Annotations[peerClusterAnnotation] != peerCluster {
+			return fmt.Errorf("refusing to import mcs %s into cluster %s: endpointslice %s/%s already exists and is not one of our imports", mcsName, peerCluster, slice.Namespace, slice.Name)
+		}
+		slice.ResourceVersion = existing.ResourceVersion
+		_, err = c.endpointSliceClient.EndpointSlices(slice.Namespace).Update(slice)
+	}
+	return err
+}
+
+// pruneImports deletes the projection for every cluster this export
+// previously imported into but that is no longer in keep, identifying its
+// own projected objects by importedServiceLabel rather than by parsing the
+// target namespace name back apart.
+func (c *controller) pruneImports(mcsName string, keep []string) error {
+	keepSet := make(map[string]bool, len(keep))
+	for _, peerCluster := range keep {
+		keepSet[peerCluster] = true
+	}
+
+	services, err := c.serviceLister.List(labels.SelectorFromSet(labels.Set{importedServiceLabel: mcsName}))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, svc := range services {
+		if keepSet[svc.Annotations[peerClusterAnnotation]] {
+			continue
+		}
+		if err := c.deleteImport(svc.Namespace, svc.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (c *controller) deleteImport(namespace, name string) error {
+	opts := &metav1.DeleteOptions{PropagationPolicy: &constants.DefaultDeletionPolicy}
+	if err := c.serviceClient.Services(namespace).Delete(name, opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := c.endpointSliceClient.EndpointSlices(namespace).Delete(name, opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *controller) AddCluster(cluster *cluster.Cluster) {
+	klog.Infof("tenant-masters-mcs-controller watch cluster %s for MultiClusterService resource", cluster.Name)
+	err := c.multiClusterMCSController.WatchClusterResource(cluster, sc.WatchOptions{})
+	if err != nil {
+		klog.Errorf("failed to watch cluster %s MultiClusterService event: %v", cluster.Name, err)
+	}
+}
+
+func (c *controller) RemoveCluster(cluster *cluster.Cluster) {
+	klog.Infof("tenant-masters-mcs-controller stop watching cluster %s for MultiClusterService resource", cluster.Name)
+	c.multiClusterMCSController.TeardownClusterResource(cluster)
+}
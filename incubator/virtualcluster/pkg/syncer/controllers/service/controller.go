@@ -18,14 +18,21 @@ package service
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	listersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+	// registers a prometheus MetricsProvider with client-go's workqueue
+	// package, so depth/adds/latency/retries for the UWS queue created below
+	// are exported automatically under the workqueue_* metric names.
+	_ "k8s.io/component-base/metrics/prometheus/workqueue"
 
 	"k8s.io/klog"
 
@@ -45,6 +52,18 @@ type controller struct {
 	serviceLister listersv1.ServiceLister
 	queue         workqueue.RateLimitingInterface
 	serviceSynced cache.InformerSynced
+
+	// allowedNamespaces and deniedNamespaces gate which tenant namespaces this
+	// controller will sync, in either direction. An empty allowedNamespaces
+	// means "all namespaces", matching the upstream Flux --k8s-allow-namespace
+	// default. deniedNamespaces always takes precedence.
+	allowedNamespaces []string
+	deniedNamespaces  []string
+
+	// serviceCapabilities records which optional Service features the super
+	// master cluster supports, so MutateService can degrade gracefully
+	// rather than producing a pService spec the super master will reject.
+	serviceCapabilities conversion.SuperMasterServiceCapabilities
 }
 
 func Register(
@@ -53,9 +72,14 @@ func Register(
 	controllerManager *manager.ControllerManager,
 ) {
 	c := &controller{
-		serviceClient: serviceClient,
-		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "super_master_service"),
-		workers:       constants.DefaultControllerWorkers,
+		serviceClient:     serviceClient,
+		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "super_master_service"),
+		workers:           constants.DefaultControllerWorkers,
+		allowedNamespaces: controllerManager.Config.AllowedNamespaces,
+		deniedNamespaces:  controllerManager.Config.DeniedNamespaces,
+		serviceCapabilities: conversion.SuperMasterServiceCapabilities{
+			DualStack: controllerManager.Config.SuperMasterDualStack,
+		},
 	}
 
 	// Create the multi cluster service controller
@@ -90,6 +114,13 @@ func Register(
 }
 
 func (c *controller) enqueueService(obj interface{}) {
+	if pService, ok := obj.(*v1.Service); ok {
+		if _, vNamespace, _, _, err := conversion.GetVirtualOwner(pService); err == nil && !c.namespaceAllowed(vNamespace) {
+			klog.Infof("skip enqueuing service %s/%s: tenant namespace %s is excluded by policy", pService.Namespace, pService.Name, vNamespace)
+			return
+		}
+	}
+
 	var key string
 	var err error
 	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
@@ -99,6 +130,27 @@ func (c *controller) enqueueService(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// namespaceAllowed reports whether the tenant namespace ns should be synced,
+// consulting deniedNamespaces before allowedNamespaces so an explicit deny
+// always wins. An empty allowedNamespaces means every namespace not denied is
+// allowed.
+func (c *controller) namespaceAllowed(ns string) bool {
+	for _, denied := range c.deniedNamespaces {
+		if denied == ns {
+			return false
+		}
+	}
+	if len(c.allowedNamespaces) == 0 {
+		return true
+	}
+	for _, allowed := range c.allowedNamespaces {
+		if allowed == ns {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 	return c.multiClusterServiceController.Start(stopCh)
 }
@@ -130,6 +182,11 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 }
 
 func (c *controller) reconcileServiceCreate(cluster, namespace, name string, service *v1.Service) error {
+	if !c.namespaceAllowed(namespace) {
+		klog.Infof("skip creating service %s/%s of cluster %s: namespace is excluded by policy", namespace, name, cluster)
+		return nil
+	}
+
 	targetNamespace := conversion.ToSuperMasterNamespace(cluster, namespace)
 	newObj, err := conversion.BuildMetadata(cluster, targetNamespace, service)
 	if err != nil {
@@ -137,7 +194,7 @@ func (c *controller) reconcileServiceCreate(cluster, namespace, name string, ser
 	}
 
 	pService := newObj.(*v1.Service)
-	conversion.MutateService(pService)
+	conversion.MutateService(pService, c.serviceCapabilities)
 
 	_, err = c.serviceClient.Services(targetNamespace).Create(pService)
 	if errors.IsAlreadyExists(err) {
@@ -148,7 +205,64 @@ func (c *controller) reconcileServiceCreate(cluster, namespace, name string, ser
 }
 
 func (c *controller) reconcileServiceUpdate(cluster, namespace, name string, service *v1.Service) error {
-	return nil
+	if !c.namespaceAllowed(namespace) {
+		klog.Infof("skip updating service %s/%s of cluster %s: namespace is excluded by policy", namespace, name, cluster)
+		return nil
+	}
+
+	targetNamespace := conversion.ToSuperMasterNamespace(cluster, namespace)
+	pService, err := c.serviceLister.Services(targetNamespace).Get(name)
+	if errors.IsNotFound(err) {
+		return c.reconcileServiceCreate(cluster, namespace, name, service)
+	}
+	if err != nil {
+		return err
+	}
+
+	newObj, err := conversion.BuildMetadata(cluster, targetNamespace, service)
+	if err != nil {
+		return err
+	}
+
+	updatedService := newObj.(*v1.Service)
+	conversion.MutateService(updatedService, c.serviceCapabilities)
+
+	updatedService.ResourceVersion = pService.ResourceVersion
+	backfillAssignedFields(pService, updatedService)
+
+	if equality.Semantic.DeepEqual(pService.Spec, updatedService.Spec) {
+		return nil
+	}
+
+	_, err = c.serviceClient.Services(targetNamespace).Update(updatedService)
+	if errors.IsConflict(err) {
+		klog.Infof("service %s/%s of cluster %s update conflict in super master, will retry", namespace, name, cluster)
+		return err
+	}
+	return err
+}
+
+// backfillAssignedFields copies the super-master-assigned ClusterIP, and each
+// port's NodePort when the tenant left it unset, from pService onto
+// updatedService. ClusterIP is allocated once by the super master and the
+// tenant never sees the real value; NodePort similarly goes to 0 in the
+// incoming spec whenever a tenant edit (e.g. changing the selector) doesn't
+// touch ports, so without this both would otherwise look like a spec change
+// and force a pointless reallocation on every reconcile.
+func backfillAssignedFields(pService, updatedService *v1.Service) {
+	updatedService.Spec.ClusterIP = pService.Spec.ClusterIP
+
+	for i := range updatedService.Spec.Ports {
+		if updatedService.Spec.Ports[i].NodePort != 0 {
+			continue
+		}
+		for _, pPort := range pService.Spec.Ports {
+			if pPort.Port == updatedService.Spec.Ports[i].Port && pPort.Protocol == updatedService.Spec.Ports[i].Protocol {
+				updatedService.Spec.Ports[i].NodePort = pPort.NodePort
+				break
+			}
+		}
+	}
 }
 
 func (c *controller) reconcileServiceRemove(cluster, namespace, name string, service *v1.Service) error {
@@ -166,6 +280,10 @@ func (c *controller) reconcileServiceRemove(cluster, namespace, name string, ser
 
 func (c *controller) AddCluster(cluster *cluster.Cluster) {
 	klog.Infof("tenant-masters-service-controller watch cluster %s for service resource", cluster.Name)
+	// namespaceAllowed is consulted per-request in reconcileServiceCreate/Update
+	// and enqueueService, not here, so the allow/deny policy applies uniformly
+	// to every cluster, including ones registered after Register ran, with no
+	// restart required.
 	err := c.multiClusterServiceController.WatchClusterResource(cluster, sc.WatchOptions{})
 	if err != nil {
 		klog.Errorf("failed to watch cluster %s service event: %v", cluster.Name, err)
@@ -173,5 +291,37 @@ func (c *controller) AddCluster(cluster *cluster.Cluster) {
 }
 
 func (c *controller) RemoveCluster(cluster *cluster.Cluster) {
-	klog.Warningf("not implemented yet")
+	klog.Infof("tenant-masters-service-controller stop watching cluster %s for service resource", cluster.Name)
+	c.multiClusterServiceController.TeardownClusterResource(cluster)
+
+	if err := c.removeNamespacedServices(cluster.Name); err != nil {
+		klog.Errorf("failed to garbage collect services of removed cluster %s: %v", cluster.Name, err)
+	}
+}
+
+// removeNamespacedServices deletes every pService left behind in the super
+// master namespaces owned by clusterName once the virtual cluster has been
+// torn down. Ownership is decided by the cluster/namespace/UID annotations
+// conversion.BuildMetadata stamped onto the pService, not by a namespace
+// string prefix match: two cluster names where one is a literal prefix of
+// the other (e.g. "foo" and "foo-x") would otherwise collide, since
+// "foo-x-default" also starts with "foo-".
+func (c *controller) removeNamespacedServices(clusterName string) error {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	opts := &metav1.DeleteOptions{PropagationPolicy: &constants.DefaultDeletionPolicy}
+	var errs []error
+	for _, pService := range services {
+		owningCluster, _, _, _, err := conversion.GetVirtualOwner(pService)
+		if err != nil || owningCluster != clusterName {
+			continue
+		}
+		if err := c.serviceClient.Services(pService.Namespace).Delete(pService.Name, opts); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
 }
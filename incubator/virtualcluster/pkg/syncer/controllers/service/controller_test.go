@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestBackfillAssignedFields(t *testing.T) {
+	pService := &v1.Service{
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.5",
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 30080},
+				{Name: "https", Port: 443, Protocol: v1.ProtocolTCP, NodePort: 30443},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		updated       v1.ServiceSpec
+		wantClusterIP string
+		wantNodePorts []int32
+	}{
+		{
+			name: "NodePort left at 0 by a tenant edit is backfilled from the pService",
+			updated: v1.ServiceSpec{
+				ClusterIP: "",
+				Ports: []v1.ServicePort{
+					{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 0},
+					{Name: "https", Port: 443, Protocol: v1.ProtocolTCP, NodePort: 0},
+				},
+			},
+			wantClusterIP: "10.0.0.5",
+			wantNodePorts: []int32{30080, 30443},
+		},
+		{
+			name: "an explicit tenant NodePort is not overwritten",
+			updated: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 31000},
+				},
+			},
+			wantClusterIP: "10.0.0.5",
+			wantNodePorts: []int32{31000},
+		},
+		{
+			name: "a port with no matching pService port keeps NodePort 0",
+			updated: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{Name: "metrics", Port: 9090, Protocol: v1.ProtocolTCP, NodePort: 0},
+				},
+			},
+			wantClusterIP: "10.0.0.5",
+			wantNodePorts: []int32{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updatedService := &v1.Service{Spec: tt.updated}
+			backfillAssignedFields(pService, updatedService)
+
+			if updatedService.Spec.ClusterIP != tt.wantClusterIP {
+				t.Errorf("ClusterIP = %q, want %q", updatedService.Spec.ClusterIP, tt.wantClusterIP)
+			}
+			if len(updatedService.Spec.Ports) != len(tt.wantNodePorts) {
+				t.Fatalf("got %d ports, want %d", len(updatedService.Spec.Ports), len(tt.wantNodePorts))
+			}
+			for i, want := range tt.wantNodePorts {
+				if got := updatedService.Spec.Ports[i].NodePort; got != want {
+					t.Errorf("Ports[%d].NodePort = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
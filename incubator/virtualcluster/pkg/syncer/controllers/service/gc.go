@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"k8s.io/klog"
+
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/constants"
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/conversion"
+)
+
+// gcSweepPeriod is how often StartGC walks every pService looking for ones
+// whose owning tenant Service (or tenant cluster) no longer exists. The
+// RemoveCluster path already reaps most of these eagerly; this sweep only
+// needs to catch the stragglers it missed (e.g. a tenant Service deleted
+// while the syncer was down).
+const gcSweepPeriod = 5 * time.Minute
+
+// StartGC runs the periodic orphan pService sweeper until stopCh is closed.
+func (c *controller) StartGC(stopCh <-chan struct{}) error {
+	wait.Until(c.sweepOrphanServices, gcSweepPeriod, stopCh)
+	return nil
+}
+
+// sweepOrphanServices deletes any pService whose owning tenant Service no
+// longer exists, using the tenant-cluster/namespace/UID annotations that
+// conversion.BuildMetadata stamped onto it at creation time.
+func (c *controller) sweepOrphanServices() {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("gc: failed to list services: %v", err)
+		return
+	}
+
+	for _, pService := range services {
+		clusterName, vNamespace, vName, vUID, err := conversion.GetVirtualOwner(pService)
+		if err != nil {
+			// Not owned by any virtual cluster; not ours to collect.
+			continue
+		}
+
+		if !c.namespaceAllowed(vNamespace) {
+			klog.Infof("gc: skip sweeping service %s/%s: tenant namespace %s is excluded by policy", pService.Namespace, pService.Name, vNamespace)
+			continue
+		}
+
+		targetCluster, err := c.multiClusterServiceController.GetCluster(clusterName)
+		if err != nil {
+			klog.Infof("gc: cluster %s of service %s/%s is gone, reaping orphan pService", clusterName, pService.Namespace, pService.Name)
+			c.deleteOrphan(pService.Namespace, pService.Name)
+			continue
+		}
+
+		tenantClient, err := targetCluster.GetClientSet()
+		if err != nil {
+			klog.Errorf("gc: failed to get client for cluster %s: %v", clusterName, err)
+			continue
+		}
+
+		vService, err := tenantClient.CoreV1().Services(vNamespace).Get(vName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			klog.Infof("gc: tenant service %s/%s of cluster %s no longer exists, reaping orphan pService %s/%s", vNamespace, vName, clusterName, pService.Namespace, pService.Name)
+			c.deleteOrphan(pService.Namespace, pService.Name)
+			continue
+		}
+		if err != nil {
+			klog.Errorf("gc: failed to check tenant service %s/%s of cluster %s: %v", vNamespace, vName, clusterName, err)
+			continue
+		}
+
+		if vService.UID != vUID {
+			klog.Infof("gc: tenant service %s/%s of cluster %s was recreated (uid %s != %s), reaping stale orphan pService %s/%s", vNamespace, vName, clusterName, vService.UID, vUID, pService.Namespace, pService.Name)
+			c.deleteOrphan(pService.Namespace, pService.Name)
+		}
+	}
+}
+
+func (c *controller) deleteOrphan(namespace, name string) {
+	opts := &metav1.DeleteOptions{PropagationPolicy: &constants.DefaultDeletionPolicy}
+	if err := c.serviceClient.Services(namespace).Delete(name, opts); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("gc: failed to delete orphan service %s/%s: %v", namespace, name, err)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/constants"
+)
+
+// newTestPService returns a pService annotated as if conversion.BuildMetadata
+// had created it for owningCluster, living in the given super master namespace.
+func newTestPService(owningCluster, namespace, name string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelCluster:   owningCluster,
+				constants.LabelNamespace: "default",
+				constants.LabelUID:       "fake-uid",
+			},
+		},
+	}
+}
+
+func TestRemoveNamespacedServices(t *testing.T) {
+	// "foo-x" is a literal prefix collision with "foo-": removing cluster
+	// "foo" must not also sweep up "foo-x"'s service.
+	foo := newTestPService("foo", "foo-default", "svc-a")
+	fooX := newTestPService("foo-x", "foo-x-default", "svc-b")
+	unowned := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"}}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, svc := range []*v1.Service{foo, fooX, unowned} {
+		if err := indexer.Add(svc); err != nil {
+			t.Fatalf("failed to seed indexer: %v", err)
+		}
+	}
+
+	fakeClient := k8sfake.NewSimpleClientset(foo, fooX, unowned)
+
+	c := &controller{
+		serviceLister: listersv1.NewServiceLister(indexer),
+		serviceClient: fakeClient.CoreV1(),
+	}
+
+	if err := c.removeNamespacedServices("foo"); err != nil {
+		t.Fatalf("removeNamespacedServices returned error: %v", err)
+	}
+
+	if _, err := fakeClient.CoreV1().Services("foo-default").Get(foo.Name, metav1.GetOptions{}); err == nil {
+		t.Error("svc-a owned by cluster \"foo\" should have been deleted")
+	}
+	if _, err := fakeClient.CoreV1().Services("foo-x-default").Get(fooX.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("svc-b owned by cluster \"foo-x\" should have survived: %v", err)
+	}
+	if _, err := fakeClient.CoreV1().Services("default").Get(unowned.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("unowned service should survive: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import "testing"
+
+func TestNamespaceAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   []string
+		denied    []string
+		namespace string
+		want      bool
+	}{
+		{
+			name:      "empty allow and deny lists allow everything",
+			namespace: "default",
+			want:      true,
+		},
+		{
+			name:      "namespace in the allow list is allowed",
+			allowed:   []string{"default", "kube-system"},
+			namespace: "default",
+			want:      true,
+		},
+		{
+			name:      "namespace not in a non-empty allow list is denied",
+			allowed:   []string{"kube-system"},
+			namespace: "default",
+			want:      false,
+		},
+		{
+			name:      "namespace in the deny list is denied",
+			denied:    []string{"default"},
+			namespace: "default",
+			want:      false,
+		},
+		{
+			name:      "deny list wins even if the namespace is also allowed",
+			allowed:   []string{"default"},
+			denied:    []string{"default"},
+			namespace: "default",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &controller{allowedNamespaces: tt.allowed, deniedNamespaces: tt.denied}
+			if got := c.namespaceAllowed(tt.namespace); got != tt.want {
+				t.Errorf("namespaceAllowed(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/klog"
+
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/conversion"
+)
+
+// uwsFullResyncPeriod is how often the upward syncer walks every pService it
+// owns and re-pushes its status, healing any drift missed by the watch.
+const uwsFullResyncPeriod = 60 * time.Second
+
+// StartUWS starts the upward syncer that watches Services in the super master
+// and patches the allocated NodePort and Status (e.g. LoadBalancer.Ingress)
+// fields back onto the owning tenant Service. It mirrors StartDWS, but runs
+// the reverse direction of sync.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.serviceSynced) {
+		return fmt.Errorf("failed to wait for service caches to sync")
+	}
+
+	defer c.queue.ShutDown()
+
+	go wait.Until(c.requeueAll, uwsFullResyncPeriod, stopCh)
+
+	for i := 0; i < c.workers; i++ {
+		go wait.Until(c.runUWSWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+	return nil
+}
+
+// requeueAll enqueues every pService known to the lister, providing the
+// periodic full resync that heals status drift the watch alone can miss.
+func (c *controller) requeueAll() {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing services for uws resync: %v", err)
+		return
+	}
+	for _, pService := range services {
+		key, err := cache.MetaNamespaceKeyFunc(pService)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		c.queue.Add(key)
+	}
+}
+
+func (c *controller) runUWSWorker() {
+	for c.processNextUWSWorkItem() {
+	}
+}
+
+func (c *controller) processNextUWSWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.backPopulateUpdate(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+
+	utilruntime.HandleError(fmt.Errorf("error processing service %v (will retry): %v", key, err))
+	c.queue.AddRateLimited(key)
+	return true
+}
+
+// backPopulateUpdate reverse-maps a pService namespace/name to its owning
+// tenant Service via the cluster/namespace annotations set by
+// conversion.BuildMetadata, and patches the tenant object's Status and any
+// super-master-assigned Spec.Ports[].NodePort values back through the vc
+// tenant client.
+func (c *controller) backPopulateUpdate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	pService, err := c.serviceLister.Services(pNamespace).Get(pName)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	clusterName, vNamespace, vName, _, err := conversion.GetVirtualOwner(pService)
+	if err != nil {
+		// Not a pService owned by a virtual cluster (e.g. a super master system Service);
+		// nothing to back populate.
+		return nil
+	}
+
+	if !c.namespaceAllowed(vNamespace) {
+		klog.Infof("skip back populating service %s/%s: tenant namespace %s is excluded by policy", pNamespace, pName, vNamespace)
+		return nil
+	}
+
+	targetCluster, err := c.multiClusterServiceController.GetCluster(clusterName)
+	if err != nil {
+		klog.Warningf("cluster %s is not registered for service %s/%s, skip back populate", clusterName, pNamespace, pName)
+		return nil
+	}
+
+	tenantClient, err := targetCluster.GetClientSet()
+	if err != nil {
+		return err
+	}
+
+	vService, err := tenantClient.CoreV1().Services(vNamespace).Get(vName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		klog.Warningf("tenant service %s/%s of cluster %s not found, skip back populate", vNamespace, vName, clusterName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	updatedService, statusChanged, portsChanged := mergeBackPopulatedFields(vService, pService)
+	if !statusChanged && !portsChanged {
+		return nil
+	}
+
+	if statusChanged {
+		// The status subresource returns the stored spec, so carry the merged
+		// ports over onto the returned object before the spec update below.
+		mergedPorts := updatedService.Spec.Ports
+		updatedService, err = tenantClient.CoreV1().Services(vNamespace).UpdateStatus(updatedService)
+		if err != nil {
+			return err
+		}
+		updatedService.Spec.Ports = mergedPorts
+	}
+
+	if portsChanged {
+		_, err = tenantClient.CoreV1().Services(vNamespace).Update(updatedService)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeBackPopulatedFields copies the super master's Status and per-port
+// NodePort values from pService onto a copy of vService, reporting whether
+// Status or Spec.Ports actually changed so the caller can skip writes the
+// tenant apiserver would see as no-ops.
+func mergeBackPopulatedFields(vService, pService *v1.Service) (updated *v1.Service, statusChanged, portsChanged bool) {
+	updated = vService.DeepCopy()
+	updated.Status = pService.Status
+
+	for i := range updated.Spec.Ports {
+		for _, pPort := range pService.Spec.Ports {
+			if pPort.Port == updated.Spec.Ports[i].Port && pPort.Protocol == updated.Spec.Ports[i].Protocol {
+				updated.Spec.Ports[i].NodePort = pPort.NodePort
+				break
+			}
+		}
+	}
+
+	statusChanged = !equality.Semantic.DeepEqual(vService.Status, updated.Status)
+	portsChanged = !equality.Semantic.DeepEqual(vService.Spec.Ports, updated.Spec.Ports)
+	return updated, statusChanged, portsChanged
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestMergeBackPopulatedFields(t *testing.T) {
+	pService := &v1.Service{
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}},
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 30080},
+			},
+		},
+	}
+
+	t.Run("status and NodePort drift are both reported as changed", func(t *testing.T) {
+		vService := &v1.Service{
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 0}},
+			},
+		}
+
+		updated, statusChanged, portsChanged := mergeBackPopulatedFields(vService, pService)
+
+		if !statusChanged {
+			t.Error("statusChanged = false, want true")
+		}
+		if !portsChanged {
+			t.Error("portsChanged = false, want true")
+		}
+		if got := updated.Spec.Ports[0].NodePort; got != 30080 {
+			t.Errorf("NodePort = %d, want 30080", got)
+		}
+		if len(updated.Status.LoadBalancer.Ingress) != 1 || updated.Status.LoadBalancer.Ingress[0].IP != "1.2.3.4" {
+			t.Errorf("Status = %+v, want ingress IP 1.2.3.4", updated.Status)
+		}
+	})
+
+	t.Run("already up to date reports no changes", func(t *testing.T) {
+		vService := &v1.Service{
+			Status: pService.Status,
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, NodePort: 30080}},
+			},
+		}
+
+		_, statusChanged, portsChanged := mergeBackPopulatedFields(vService, pService)
+
+		if statusChanged {
+			t.Error("statusChanged = true, want false")
+		}
+		if portsChanged {
+			t.Error("portsChanged = true, want false")
+		}
+	})
+}
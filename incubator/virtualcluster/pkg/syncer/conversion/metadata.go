@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conversion builds and mutates the super master copy (pObject) of a
+// tenant object (vObject), and reverses that mapping for upward sync.
+package conversion
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-sigs/multi-tenancy/incubator/virtualcluster/pkg/syncer/constants"
+)
+
+// ToSuperMasterNamespace returns the super master namespace backing namespace
+// in cluster. Passing an empty namespace yields the common prefix shared by
+// every namespace cluster owns, e.g. for use with strings.HasPrefix.
+func ToSuperMasterNamespace(cluster, namespace string) string {
+	return fmt.Sprintf("%s-%s", cluster, namespace)
+}
+
+// BuildMetadata deep copies obj, points it at targetNamespace, strips the
+// fields that must be assigned fresh by the super master, and stamps it with
+// the cluster/namespace/UID annotations GetVirtualOwner later reads back.
+func BuildMetadata(cluster, targetNamespace string, obj runtime.Object) (runtime.Object, error) {
+	newObj := obj.DeepCopyObject()
+	metaObj, ok := newObj.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %T does not implement metav1.Object", newObj)
+	}
+
+	vNamespace := metaObj.GetNamespace()
+	vUID := metaObj.GetUID()
+
+	metaObj.SetNamespace(targetNamespace)
+	metaObj.SetResourceVersion("")
+	metaObj.SetUID("")
+	metaObj.SetSelfLink("")
+
+	annotations := metaObj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[constants.LabelCluster] = cluster
+	annotations[constants.LabelNamespace] = vNamespace
+	annotations[constants.LabelUID] = string(vUID)
+	metaObj.SetAnnotations(annotations)
+
+	return newObj, nil
+}
+
+// GetVirtualOwner reverse-maps a pObject back to the tenant cluster,
+// namespace, name and UID that own it, using the annotations BuildMetadata
+// stamped onto it. It returns an error if obj was not created by BuildMetadata.
+// The returned UID lets a caller detect that the tenant object was deleted
+// and recreated since the pObject was last synced, since a Get by namespace/
+// name alone would otherwise match the new object and miss the stale pObject.
+func GetVirtualOwner(obj metav1.Object) (cluster, namespace, name string, uid types.UID, err error) {
+	annotations := obj.GetAnnotations()
+	cluster, ok := annotations[constants.LabelCluster]
+	if !ok {
+		return "", "", "", "", fmt.Errorf("object %s/%s has no virtual owner annotations", obj.GetNamespace(), obj.GetName())
+	}
+	namespace = annotations[constants.LabelNamespace]
+	return cluster, namespace, obj.GetName(), types.UID(annotations[constants.LabelUID]), nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// SuperMasterServiceCapabilities describes which optional Service features
+// the super master cluster supports, so MutateService can degrade a tenant
+// spec gracefully instead of producing a pService the API server will reject.
+type SuperMasterServiceCapabilities struct {
+	// DualStack indicates the super master apiserver has the IPv6DualStack
+	// feature enabled and can honor IPFamilies/IPFamilyPolicy.
+	DualStack bool
+}
+
+// MutateService normalizes a tenant Service spec into one that is safe to
+// create or update in the super master, while preserving the fields that
+// make ExternalName, headless and dual-stack Services behave the way the
+// tenant expects.
+func MutateService(pService *v1.Service, capabilities SuperMasterServiceCapabilities) {
+	if pService.Spec.Type == v1.ServiceTypeExternalName {
+		// ExternalName Services have no ClusterIP, no ports to allocate
+		// NodePorts for, and no endpoints; there is nothing else to mutate.
+		pService.Spec.ClusterIP = ""
+		return
+	}
+
+	if pService.Spec.ClusterIP == v1.ClusterIPNone {
+		// Headless: keep "None" as-is so the super master endpoints syncer
+		// produces matching subsets instead of allocating a real ClusterIP.
+		pService.Spec.ClusterIP = v1.ClusterIPNone
+	} else {
+		// A tenant never gets to pick its own ClusterIP; let the super
+		// master allocate one on create, and reconcileServiceUpdate is
+		// responsible for preserving it across updates.
+		pService.Spec.ClusterIP = ""
+	}
+
+	if !capabilities.DualStack {
+		pService.Spec.IPFamilies = nil
+		pService.Spec.IPFamilyPolicy = nil
+	}
+
+	// SessionAffinityConfig, InternalTrafficPolicy and TopologyKeys are plain
+	// pod/traffic-routing policy with no super-master-assigned state, so they
+	// pass through unchanged.
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestMutateService(t *testing.T) {
+	dualStackFamilyPolicy := v1.IPFamilyPolicyPreferDualStack
+
+	tests := []struct {
+		name         string
+		in           v1.ServiceSpec
+		capabilities SuperMasterServiceCapabilities
+		want         v1.ServiceSpec
+	}{
+		{
+			name: "ClusterIP service gets its ClusterIP cleared for super-master allocation",
+			in:   v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "10.0.0.5"},
+			want: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: ""},
+		},
+		{
+			name: "headless service keeps ClusterIP None",
+			in:   v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: v1.ClusterIPNone},
+			want: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: v1.ClusterIPNone},
+		},
+		{
+			name: "ExternalName service is passed through without ClusterIP allocation",
+			in:   v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "example.com"},
+			want: v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "example.com", ClusterIP: ""},
+		},
+		{
+			name:         "dual-stack fields are dropped when the super master lacks the capability",
+			in:           v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}, IPFamilyPolicy: &dualStackFamilyPolicy},
+			capabilities: SuperMasterServiceCapabilities{DualStack: false},
+			want:         v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "", IPFamilies: nil, IPFamilyPolicy: nil},
+		},
+		{
+			name:         "dual-stack fields are preserved when the super master supports it",
+			in:           v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}, IPFamilyPolicy: &dualStackFamilyPolicy},
+			capabilities: SuperMasterServiceCapabilities{DualStack: true},
+			want:         v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "", IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}, IPFamilyPolicy: &dualStackFamilyPolicy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pService := &v1.Service{Spec: tt.in}
+			MutateService(pService, tt.capabilities)
+
+			if pService.Spec.ClusterIP != tt.want.ClusterIP {
+				t.Errorf("ClusterIP = %q, want %q", pService.Spec.ClusterIP, tt.want.ClusterIP)
+			}
+			if len(pService.Spec.IPFamilies) != len(tt.want.IPFamilies) {
+				t.Errorf("IPFamilies = %v, want %v", pService.Spec.IPFamilies, tt.want.IPFamilies)
+			}
+			if (pService.Spec.IPFamilyPolicy == nil) != (tt.want.IPFamilyPolicy == nil) {
+				t.Errorf("IPFamilyPolicy = %v, want %v", pService.Spec.IPFamilyPolicy, tt.want.IPFamilyPolicy)
+			}
+		})
+	}
+}